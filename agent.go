@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Agent is a named profile that scopes the assistant to a system prompt,
+// a whitelist of tool names, and a set of files that are always attached
+// as context. Profiles are loaded from
+// ~/.config/keploy-agent/agents/*.yaml and layered on top of the
+// built-in profiles below.
+type Agent struct {
+	Name         string   `yaml:"name" json:"name"`
+	SystemPrompt string   `yaml:"systemPrompt" json:"systemPrompt"`
+	Tools        []string `yaml:"tools" json:"tools"`
+	PinnedFiles  []string `yaml:"pinnedFiles" json:"pinnedFiles"`
+}
+
+// builtinAgents ships with the CLI so a usable default exists even
+// before the user writes their own YAML profiles.
+var builtinAgents = []Agent{
+	{
+		Name:         "coder",
+		SystemPrompt: "You are a general-purpose coding assistant with full access to the repository.",
+		Tools:        []string{"read_file", "write_file", "edit_file", "list_files", "search_files", "run_command"},
+	},
+	{
+		Name:         "test-writer",
+		SystemPrompt: "You write and improve unit tests for the given code, favoring the repo's existing test style.",
+		Tools:        []string{"read_file", "list_files", "search_files", "generate_unit_tests"},
+	},
+	{
+		Name:         "researcher",
+		SystemPrompt: "You research topics using the web and summarize findings with sources.",
+		Tools:        []string{"web_search", "url_extract"},
+	},
+}
+
+func agentsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "keploy-agent", "agents"), nil
+}
+
+// loadAgents returns the built-in agents overlaid with any user-defined
+// profiles from ~/.config/keploy-agent/agents/*.yaml. A user profile
+// with the same Name as a built-in replaces it.
+func loadAgents() []Agent {
+	agents := make([]Agent, len(builtinAgents))
+	copy(agents, builtinAgents)
+
+	dir, err := agentsDir()
+	if err != nil {
+		return agents
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return agents
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		var a Agent
+		if err := yaml.Unmarshal(data, &a); err != nil || a.Name == "" {
+			continue
+		}
+
+		replaced := false
+		for i, existing := range agents {
+			if existing.Name == a.Name {
+				agents[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			agents = append(agents, a)
+		}
+	}
+
+	return agents
+}
+
+// agentByName returns the agent with the given name, or false if none matches.
+func agentByName(agents []Agent, name string) (Agent, bool) {
+	for _, a := range agents {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Agent{}, false
+}
+
+// PinnedFileContents reads each of the agent's pinned files relative to
+// workDir, keyed by the path as declared in the profile. Unreadable
+// files are silently skipped so a stale pin doesn't block startup.
+func (a Agent) PinnedFileContents(workDir string) map[string]string {
+	contents := make(map[string]string, len(a.PinnedFiles))
+	for _, path := range a.PinnedFiles {
+		full := path
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(workDir, path)
+		}
+		data, err := os.ReadFile(full)
+		if err != nil {
+			continue
+		}
+		contents[path] = string(data)
+	}
+	return contents
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAgentByNameLookup(t *testing.T) {
+	agents := []Agent{{Name: "coder"}, {Name: "researcher"}}
+
+	if got, ok := agentByName(agents, "researcher"); !ok || got.Name != "researcher" {
+		t.Errorf("agentByName(..., %q) = (%v, %v), want (researcher, true)", "researcher", got, ok)
+	}
+	if _, ok := agentByName(agents, "missing"); ok {
+		t.Error("agentByName(..., \"missing\") ok = true, want false")
+	}
+}
+
+func writeAgentYAML(t *testing.T, dir, filename, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0o644); err != nil {
+		t.Fatalf("writing %s: %v", filename, err)
+	}
+}
+
+func TestLoadAgentsWithNoUserProfilesReturnsBuiltins(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	agents := loadAgents()
+	if len(agents) != len(builtinAgents) {
+		t.Fatalf("loadAgents() returned %d agents, want %d built-ins", len(agents), len(builtinAgents))
+	}
+	for i, a := range agents {
+		if a.Name != builtinAgents[i].Name {
+			t.Errorf("agents[%d].Name = %q, want %q", i, a.Name, builtinAgents[i].Name)
+		}
+	}
+}
+
+func TestLoadAgentsUserProfileOverridesBuiltin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, ".config", "keploy-agent", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeAgentYAML(t, dir, "coder.yaml", "name: coder\nsystemPrompt: custom coder prompt\ntools: [read_file]\n")
+
+	agents := loadAgents()
+	if len(agents) != len(builtinAgents) {
+		t.Fatalf("loadAgents() returned %d agents, want %d (override, not append)", len(agents), len(builtinAgents))
+	}
+
+	coder, ok := agentByName(agents, "coder")
+	if !ok {
+		t.Fatal("loadAgents() lost the coder agent")
+	}
+	if coder.SystemPrompt != "custom coder prompt" {
+		t.Errorf("coder.SystemPrompt = %q, want the user override", coder.SystemPrompt)
+	}
+}
+
+func TestLoadAgentsUserProfileAppendsNewAgent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, ".config", "keploy-agent", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeAgentYAML(t, dir, "reviewer.yaml", "name: reviewer\nsystemPrompt: review code\ntools: [read_file]\n")
+
+	agents := loadAgents()
+	if len(agents) != len(builtinAgents)+1 {
+		t.Fatalf("loadAgents() returned %d agents, want %d (built-ins + 1 new)", len(agents), len(builtinAgents)+1)
+	}
+	if _, ok := agentByName(agents, "reviewer"); !ok {
+		t.Error("loadAgents() did not include the new reviewer profile")
+	}
+}
+
+func TestLoadAgentsSkipsInvalidAndNonYAMLFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, ".config", "keploy-agent", "agents")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeAgentYAML(t, dir, "noname.yaml", "systemPrompt: missing a name\n")
+	writeAgentYAML(t, dir, "notes.txt", "not a profile at all")
+
+	agents := loadAgents()
+	if len(agents) != len(builtinAgents) {
+		t.Fatalf("loadAgents() returned %d agents, want %d (invalid/non-YAML files skipped)", len(agents), len(builtinAgents))
+	}
+}
+
+func TestPinnedFileContentsSkipsUnreadableFiles(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "present.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := Agent{PinnedFiles: []string{"present.txt", "missing.txt"}}
+	contents := a.PinnedFileContents(workDir)
+
+	if len(contents) != 1 {
+		t.Fatalf("PinnedFileContents() returned %d entries, want 1", len(contents))
+	}
+	if contents["present.txt"] != "hello" {
+		t.Errorf("PinnedFileContents()[present.txt] = %q, want %q", contents["present.txt"], "hello")
+	}
+	if _, ok := contents["missing.txt"]; ok {
+		t.Error("PinnedFileContents() included an unreadable file")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// AgentClient owns the lifecycle of the TypeScript agent subprocess:
+// starting it, sending it messages, and reading its replies line by
+// line. It has no dependency on bubbletea so both the TUI and the
+// headless "prompt" command can drive the same process.
+type AgentClient struct {
+	process *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// NewAgentClient returns an unstarted client. Call Start before sending
+// or receiving anything.
+func NewAgentClient() *AgentClient {
+	return &AgentClient{}
+}
+
+// Start launches the agent process, wires up its pipes, and sends the
+// MsgInit handshake built from the chosen provider and agent profile.
+func (c *AgentClient) Start(provider Provider, apiKey, baseURL string, agent Agent, workDir string) error {
+	if _, err := os.Stat("./agent"); os.IsNotExist(err) {
+		return fmt.Errorf("agent directory not found. Please run from the keploy-agent directory")
+	}
+
+	cmd := exec.Command("npm", "start")
+	cmd.Dir = "./agent"
+	cmd.Env = os.Environ()
+	for k, v := range provider.EnvVars(apiKey) {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	if errFile, err := os.Create("agent-error.log"); err == nil {
+		cmd.Stderr = errFile
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start agent: %w", err)
+	}
+
+	c.process = cmd
+	c.stdin = stdin
+	c.stdout = stdout
+	c.scanner = bufio.NewScanner(stdout)
+
+	payload := provider.InitPayload(apiKey, baseURL, provider.DefaultModel())
+	payload["agentSystemPrompt"] = agent.SystemPrompt
+	payload["agentTools"] = agent.Tools
+	payload["agentPinnedFiles"] = agent.PinnedFileContents(workDir)
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal init payload: %w", err)
+	}
+
+	return c.send(AgentMessage{Type: MsgInit, Data: json.RawMessage(payloadBytes)})
+}
+
+func (c *AgentClient) send(msg AgentMessage) error {
+	if c.stdin == nil {
+		return fmt.Errorf("agent not initialized")
+	}
+
+	msgBytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.stdin.Write(msgBytes); err != nil {
+		return err
+	}
+	_, err = c.stdin.Write([]byte("\n"))
+	return err
+}
+
+// SendChat sends a MsgChat with the given user message.
+func (c *AgentClient) SendChat(message string) error {
+	payload, err := json.Marshal(struct {
+		Message string `json:"message"`
+	}{message})
+	if err != nil {
+		return err
+	}
+	return c.send(AgentMessage{Type: MsgChat, Data: json.RawMessage(payload)})
+}
+
+// SendToolDecision replies to a pending tool_call_request.
+func (c *AgentClient) SendToolDecision(id, decision string) error {
+	payload, err := json.Marshal(ToolCallDecision{ID: id, Decision: decision})
+	if err != nil {
+		return err
+	}
+	return c.send(AgentMessage{Type: MsgToolCallDecision, Data: json.RawMessage(payload)})
+}
+
+// Next blocks until the next line of agent output is available and
+// returns it decoded as an AgentMessage. It returns io.EOF once the
+// agent closes its stdout.
+func (c *AgentClient) Next() (AgentMessage, error) {
+	if c.scanner == nil {
+		return AgentMessage{}, fmt.Errorf("agent stdout is nil")
+	}
+
+	for c.scanner.Scan() {
+		line := c.scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		var msg AgentMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			continue
+		}
+		return msg, nil
+	}
+
+	if err := c.scanner.Err(); err != nil {
+		return AgentMessage{}, fmt.Errorf("agent stream error: %w", err)
+	}
+	return AgentMessage{}, io.EOF
+}
+
+// Kill terminates the agent process, if running.
+func (c *AgentClient) Kill() {
+	if c.process != nil && c.process.Process != nil {
+		c.process.Process.Kill()
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Config is the persisted TUI configuration stored under
+// ~/.config/keploy-agent/config.json.
+type Config struct {
+	LastProvider     string          `json:"lastProvider"`
+	LastBaseURL      string          `json:"lastBaseURL"`
+	AutoApproveTools map[string]bool `json:"autoApproveTools,omitempty"`
+}
+
+func configPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "keploy-agent", "config.json"), nil
+}
+
+// loadConfig reads the persisted config, returning a zero-value Config
+// if it doesn't exist yet or can't be read.
+func loadConfig() Config {
+	path, err := configPath()
+	if err != nil {
+		return Config{}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}
+	}
+	return cfg
+}
+
+// saveConfig persists cfg to ~/.config/keploy-agent/config.json,
+// creating the directory if needed.
+func saveConfig(cfg Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoredMessage is a single node in a conversation's message tree.
+// ParentID is empty for the root message of a conversation; every other
+// message points back at the message it was sent in reply to, so a
+// conversation can hold multiple branches created by editing and
+// re-prompting from an earlier point. Role is "user", "assistant",
+// "tool", or "system" - every line shown in the transcript is recorded
+// here so nothing is lost when the viewport is rebuilt from the tree.
+type StoredMessage struct {
+	ID        string    `json:"id"`
+	ParentID  string    `json:"parentId"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+	IsError   bool      `json:"isError,omitempty"`
+}
+
+// Conversation is a persisted chat, stored as a tree of messages plus a
+// pointer to the currently active leaf (HeadID). updateViewport only
+// ever renders the path from the root to HeadID.
+type Conversation struct {
+	ID        string                    `json:"id"`
+	Title     string                    `json:"title"`
+	CreatedAt time.Time                 `json:"createdAt"`
+	UpdatedAt time.Time                 `json:"updatedAt"`
+	Messages  map[string]*StoredMessage `json:"messages"`
+	HeadID    string                    `json:"headId"`
+}
+
+// ConversationSummary is the lightweight listing shown in StateConversationList.
+type ConversationSummary struct {
+	ID        string
+	Title     string
+	UpdatedAt time.Time
+}
+
+func conversationsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".local", "share", "keploy-agent", "conversations"), nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(b)
+}
+
+// titleMaxRunes bounds the conversation title derived from the first
+// user message, keeping StateConversationList rows to one line.
+const titleMaxRunes = 40
+
+// deriveTitle turns a user message into a short, single-line title for
+// the conversation list, truncating long messages with an ellipsis.
+func deriveTitle(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if content == "" {
+		return "New conversation"
+	}
+
+	runes := []rune(content)
+	if len(runes) > titleMaxRunes {
+		return string(runes[:titleMaxRunes]) + "..."
+	}
+	return content
+}
+
+// newConversation creates an empty, unsaved conversation. Title is
+// filled in by AddMessage once the first user message arrives.
+func newConversation() *Conversation {
+	now := time.Now()
+	return &Conversation{
+		ID:        newID(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Messages:  map[string]*StoredMessage{},
+	}
+}
+
+// AddMessage appends a message as a child of parentID (or as a new root
+// if parentID is empty) and moves HeadID to it. The conversation's title
+// is derived from the first user message it sees.
+func (c *Conversation) AddMessage(parentID, role, content string, isError bool) *StoredMessage {
+	msg := &StoredMessage{
+		ID:        newID(),
+		ParentID:  parentID,
+		Role:      role,
+		Content:   content,
+		Timestamp: time.Now(),
+		IsError:   isError,
+	}
+	c.Messages[msg.ID] = msg
+	c.HeadID = msg.ID
+	c.UpdatedAt = msg.Timestamp
+	if c.Title == "" && role == "user" {
+		c.Title = deriveTitle(content)
+	}
+	return msg
+}
+
+// ActiveBranch walks from the root up to HeadID and returns the messages
+// on that path in chronological order - the branch currently shown in
+// the viewport.
+func (c *Conversation) ActiveBranch() []*StoredMessage {
+	var branch []*StoredMessage
+	id := c.HeadID
+	for id != "" {
+		msg, ok := c.Messages[id]
+		if !ok {
+			break
+		}
+		branch = append([]*StoredMessage{msg}, branch...)
+		id = msg.ParentID
+	}
+	return branch
+}
+
+func conversationPath(dir, id string) string {
+	return filepath.Join(dir, id+".json")
+}
+
+// saveConversation persists c to its JSON file under the conversations dir.
+func saveConversation(c *Conversation) error {
+	dir, err := conversationsDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(conversationPath(dir, c.ID), data, 0o644)
+}
+
+// loadConversation reads a single conversation by ID.
+func loadConversation(id string) (*Conversation, error) {
+	dir, err := conversationsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(conversationPath(dir, id))
+	if err != nil {
+		return nil, err
+	}
+
+	var c Conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// deleteConversation removes a conversation's file from disk.
+func deleteConversation(id string) error {
+	dir, err := conversationsDir()
+	if err != nil {
+		return err
+	}
+	return os.Remove(conversationPath(dir, id))
+}
+
+// listConversations returns summaries of all persisted conversations,
+// most recently updated first.
+func listConversations() ([]ConversationSummary, error) {
+	dir, err := conversationsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []ConversationSummary
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		c, err := loadConversation(id)
+		if err != nil {
+			continue
+		}
+
+		summaries = append(summaries, ConversationSummary{
+			ID:        c.ID,
+			Title:     c.Title,
+			UpdatedAt: c.UpdatedAt,
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+	})
+
+	return summaries, nil
+}
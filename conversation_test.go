@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestConversationAddMessageSetsTitleFromFirstUserMessage(t *testing.T) {
+	c := newConversation()
+
+	c.AddMessage(c.HeadID, "system", "✓ Agent initialized", false)
+	if c.Title != "" {
+		t.Fatalf("Title = %q after a system message, want empty", c.Title)
+	}
+
+	c.AddMessage(c.HeadID, "user", "hello there, how are you today?", false)
+	if c.Title != "hello there, how are you today?" {
+		t.Fatalf("Title = %q, want derived from first user message", c.Title)
+	}
+
+	c.AddMessage(c.HeadID, "user", "a second message", false)
+	if c.Title != "hello there, how are you today?" {
+		t.Fatalf("Title changed to %q on a later user message, want it to stay fixed", c.Title)
+	}
+}
+
+func TestDeriveTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "", "New conversation"},
+		{"short", "fix the bug", "fix the bug"},
+		{"collapses whitespace", "fix   the\nbug", "fix the bug"},
+		{
+			"truncates long content",
+			"this is a very long message that definitely exceeds the title rune limit for sure",
+			"this is a very long message that definit...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deriveTitle(tt.content); got != tt.want {
+				t.Errorf("deriveTitle(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConversationActiveBranchFollowsHeadIgnoringOtherBranches(t *testing.T) {
+	c := newConversation()
+
+	root := c.AddMessage("", "user", "root", false)
+	childA := c.AddMessage(root.ID, "assistant", "branch A", false)
+	_ = childA
+
+	// Fork from root down a second branch and leave HeadID there.
+	childB := c.AddMessage(root.ID, "assistant", "branch B", false)
+
+	branch := c.ActiveBranch()
+	if len(branch) != 2 {
+		t.Fatalf("len(ActiveBranch()) = %d, want 2", len(branch))
+	}
+	if branch[0].ID != root.ID || branch[1].ID != childB.ID {
+		t.Fatalf("ActiveBranch() = %v, want [root, branch B]", branch)
+	}
+}
+
+func TestConversationActiveBranchEmptyForFreshConversation(t *testing.T) {
+	c := newConversation()
+	if branch := c.ActiveBranch(); len(branch) != 0 {
+		t.Fatalf("ActiveBranch() = %v on a fresh conversation, want empty", branch)
+	}
+}
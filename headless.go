@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// headlessAPIKeyEnvVar returns the environment variable a provider reads
+// its API key from, and false for providers (Ollama) that don't need one.
+func headlessAPIKeyEnvVar(p Provider) (string, bool) {
+	for k := range p.EnvVars("") {
+		return k, true
+	}
+	return "", false
+}
+
+// runHeadlessPrompt drives one turn of the agent without the TUI: start
+// the process, send a single message, stream the reply to stdout, and
+// exit. It shares AgentClient with the interactive Model so both paths
+// speak the exact same wire protocol.
+func runHeadlessPrompt(agentName, message string) error {
+	cfg := loadConfig()
+	provider := ProviderByName(cfg.LastProvider)
+
+	var apiKey, baseURL string
+	if envVar, ok := headlessAPIKeyEnvVar(provider); ok {
+		apiKey = os.Getenv(envVar)
+		if apiKey == "" {
+			return fmt.Errorf("%s is not set; export it or run the interactive TUI once to select a provider", envVar)
+		}
+	} else {
+		baseURL = cfg.LastBaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+	}
+
+	agents := loadAgents()
+	agent, ok := agentByName(agents, agentName)
+	if !ok {
+		agent = agents[0]
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		workDir = "."
+	}
+
+	client := NewAgentClient()
+	if err := client.Start(provider, apiKey, baseURL, agent, workDir); err != nil {
+		return err
+	}
+	defer client.Kill()
+
+	if err := client.SendChat(message); err != nil {
+		return err
+	}
+
+	// printed tracks how much of the cumulative assistant content (the
+	// protocol sends the full text so far on every chunk, not a delta -
+	// see the TUI's MsgStreamChunk handling in main.go) has already hit
+	// stdout, so only the new suffix is printed on each chunk.
+	var printed int
+	printSuffix := func(content string) {
+		if len(content) > printed {
+			fmt.Print(content[printed:])
+			printed = len(content)
+		}
+	}
+
+	for {
+		agentMsg, err := client.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		switch agentMsg.Type {
+		case MsgResponse:
+			var resp struct {
+				Status  string `json:"status"`
+				Content string `json:"content"`
+			}
+			json.Unmarshal(agentMsg.Data, &resp)
+			if resp.Status == "initialized" {
+				continue
+			}
+			if resp.Content != "" {
+				printSuffix(resp.Content)
+				fmt.Println()
+				return nil
+			}
+
+		case MsgStreamChunk:
+			var chunk struct {
+				Content string `json:"content"`
+			}
+			json.Unmarshal(agentMsg.Data, &chunk)
+			if chunk.Content != "" {
+				printSuffix(chunk.Content)
+			}
+
+		case MsgToolCall:
+			var toolData ToolCallInfo
+			json.Unmarshal(agentMsg.Data, &toolData)
+			fmt.Fprintln(os.Stderr, formatToolCallSummary(toolData.ToolName, toolData.Args))
+
+		case MsgToolCallRequest:
+			var req ToolCallRequest
+			json.Unmarshal(agentMsg.Data, &req)
+
+			decision := DecisionDeny
+			if !isAlwaysGated(req.ToolName) && (cfg.AutoApproveTools[req.ToolName] || isReadOnlyTool(req.ToolName)) {
+				decision = DecisionApprove
+			}
+			fmt.Fprintf(os.Stderr, "%s -> %s\n", formatToolCallSummary(req.ToolName, req.Args), decision)
+			client.SendToolDecision(req.ID, decision)
+
+		case MsgError:
+			var errData struct {
+				Message string `json:"message"`
+			}
+			json.Unmarshal(agentMsg.Data, &errData)
+			return fmt.Errorf("agent error: %s", errData.Message)
+		}
+	}
+}
@@ -1,13 +1,12 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -16,6 +15,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -23,12 +23,14 @@ import (
 type MessageType string
 
 const (
-	MsgInit        MessageType = "init"
-	MsgChat        MessageType = "chat"
-	MsgError       MessageType = "error"
-	MsgResponse    MessageType = "response"
-	MsgToolCall    MessageType = "tool_call"
-	MsgStreamChunk MessageType = "stream_chunk"
+	MsgInit             MessageType = "init"
+	MsgChat             MessageType = "chat"
+	MsgError            MessageType = "error"
+	MsgResponse         MessageType = "response"
+	MsgToolCall         MessageType = "tool_call"
+	MsgStreamChunk      MessageType = "stream_chunk"
+	MsgToolCallRequest  MessageType = "tool_call_request"
+	MsgToolCallDecision MessageType = "tool_call_decision"
 )
 
 type AgentMessage struct {
@@ -40,16 +42,83 @@ type AgentMessage struct {
 type AppState int
 
 const (
-	StateAPIKey AppState = iota
+	StateProviderSelect AppState = iota
+	StateAPIKey
+	StateBaseURL
+	StateAgentSelect
+	StateConversationList
 	StateChat
 )
 
 // Chat message for display
 type ChatMessage struct {
+	ID        string // corresponding StoredMessage.ID in the active conversation, "" if not yet persisted
 	Role      string // "user", "assistant", "system", "tool"
 	Content   string
 	Timestamp time.Time
 	IsError   bool
+
+	// renderedCache holds the markdown-rendered form of Content for
+	// "assistant" messages, computed lazily and invalidated whenever
+	// Content or the render width changes (see rendered).
+	renderedCache   string
+	renderedContent string
+	renderedWidth   int
+}
+
+// rendered returns the markdown-rendered form of an assistant message,
+// re-rendering only when Content or width has changed since the last
+// call so streaming updates don't re-render the whole conversation.
+// Non-assistant messages are returned as plain text.
+func (msg *ChatMessage) rendered(renderer *glamour.TermRenderer, width int) string {
+	if msg.Role != "assistant" || renderer == nil {
+		return msg.Content
+	}
+
+	if msg.renderedContent == msg.Content && msg.renderedWidth == width {
+		return msg.renderedCache
+	}
+
+	out, err := renderer.Render(msg.Content)
+	if err != nil {
+		return msg.Content
+	}
+
+	msg.renderedCache = strings.TrimRight(out, "\n")
+	msg.renderedContent = msg.Content
+	msg.renderedWidth = width
+	return msg.renderedCache
+}
+
+// chatMessagesFromConversation converts a conversation's active branch
+// into the flat []ChatMessage the viewport renders. prev supplies the
+// previously rendered messages (matched by ID) so unchanged assistant
+// messages keep their cached markdown render instead of recomputing it
+// on every rebuild.
+func chatMessagesFromConversation(c *Conversation, prev []ChatMessage) []ChatMessage {
+	prevByID := make(map[string]*ChatMessage, len(prev))
+	for i := range prev {
+		prevByID[prev[i].ID] = &prev[i]
+	}
+
+	branch := c.ActiveBranch()
+	msgs := make([]ChatMessage, 0, len(branch))
+	for _, m := range branch {
+		cm := ChatMessage{
+			ID:        m.ID,
+			Role:      m.Role,
+			Content:   m.Content,
+			Timestamp: m.Timestamp,
+			IsError:   m.IsError,
+		}
+		if old, ok := prevByID[m.ID]; ok && old.renderedContent == cm.Content {
+			cm.renderedCache = old.renderedCache
+			cm.renderedContent = old.renderedContent
+			cm.renderedWidth = old.renderedWidth
+		}
+		msgs = append(msgs, cm)
+	}
+	return msgs
 }
 
 // Tool call information
@@ -58,22 +127,156 @@ type ToolCallInfo struct {
 	Args     map[string]interface{} `json:"args"`
 }
 
+// formatToolCallSummary renders a concise single-line description of a
+// tool call, e.g. "🔧 Tool: read_file | file: main.go". Used both for
+// the after-the-fact MsgToolCall log line and for previewing a pending
+// MsgToolCallRequest before it runs.
+func formatToolCallSummary(toolName string, args map[string]interface{}) string {
+	toolMsg := fmt.Sprintf("🔧 Tool: %s", toolName)
+
+	if len(args) == 0 {
+		return toolMsg
+	}
+
+	var params []string
+
+	// Special handling for common tools to show most relevant info
+	switch toolName {
+	case "read_file", "write_file", "edit_file":
+		if filePath, ok := args["filePath"].(string); ok {
+			params = append(params, fmt.Sprintf("file: %s", filePath))
+		}
+	case "list_files":
+		if dirPath, ok := args["dirPath"].(string); ok {
+			params = append(params, fmt.Sprintf("dir: %s", dirPath))
+		}
+		if recursive, ok := args["recursive"].(bool); ok && recursive {
+			params = append(params, "recursive")
+		}
+	case "search_files":
+		if pattern, ok := args["pattern"].(string); ok {
+			params = append(params, fmt.Sprintf("pattern: \"%s\"", pattern))
+		}
+		if dir, ok := args["directory"].(string); ok && dir != "." {
+			params = append(params, fmt.Sprintf("in: %s", dir))
+		}
+	case "run_command":
+		if cmd, ok := args["command"].(string); ok {
+			// Truncate long commands
+			if len(cmd) > 50 {
+				params = append(params, fmt.Sprintf("cmd: %s...", cmd[:50]))
+			} else {
+				params = append(params, fmt.Sprintf("cmd: %s", cmd))
+			}
+		}
+	case "web_search":
+		if query, ok := args["query"].(string); ok {
+			params = append(params, fmt.Sprintf("query: \"%s\"", query))
+		}
+		if limit, ok := args["limit"].(float64); ok && limit != 3 {
+			params = append(params, fmt.Sprintf("limit: %d", int(limit)))
+		}
+		if scrape, ok := args["scrape"].(bool); ok && scrape {
+			params = append(params, "scrape: true")
+		}
+	case "url_extract":
+		if url, ok := args["url"].(string); ok {
+			// Truncate long URLs
+			if len(url) > 50 {
+				params = append(params, fmt.Sprintf("url: %s...", url[:50]))
+			} else {
+				params = append(params, fmt.Sprintf("url: %s", url))
+			}
+		}
+		if formats, ok := args["formats"].([]interface{}); ok && len(formats) > 0 {
+			formatStrs := make([]string, 0)
+			for _, f := range formats {
+				if fStr, ok := f.(string); ok {
+					formatStrs = append(formatStrs, fStr)
+				}
+			}
+			if len(formatStrs) > 0 {
+				params = append(params, fmt.Sprintf("formats: [%s]", strings.Join(formatStrs, ",")))
+			}
+		}
+	case "generate_unit_tests":
+		if filePath, ok := args["filePath"].(string); ok {
+			params = append(params, fmt.Sprintf("file: %s", filePath))
+		}
+		if testFramework, ok := args["testFramework"].(string); ok && testFramework != "testing" {
+			params = append(params, fmt.Sprintf("framework: %s", testFramework))
+		}
+		if coverageTarget, ok := args["coverageTarget"].(float64); ok {
+			params = append(params, fmt.Sprintf("coverage: %d%%", int(coverageTarget)))
+		}
+	default:
+		// Generic handling for unknown tools
+		for key, value := range args {
+			var valueStr string
+			switch v := value.(type) {
+			case string:
+				if len(v) > 30 {
+					valueStr = fmt.Sprintf("\"%s...\"", v[:30])
+				} else {
+					valueStr = fmt.Sprintf("\"%s\"", v)
+				}
+			case bool:
+				valueStr = fmt.Sprintf("%v", v)
+			case float64:
+				if v == float64(int(v)) {
+					valueStr = fmt.Sprintf("%d", int(v))
+				} else {
+					valueStr = fmt.Sprintf("%v", v)
+				}
+			default:
+				valueStr = fmt.Sprintf("%v", v)
+			}
+			params = append(params, fmt.Sprintf("%s: %s", key, valueStr))
+		}
+	}
+
+	if len(params) > 0 {
+		toolMsg += " | " + strings.Join(params, ", ")
+	}
+
+	return toolMsg
+}
+
 // Model for our Keploy Agent application
 type Model struct {
-	state        AppState
-	apiKeyInput  textinput.Model
-	chatInput    textarea.Model
-	viewport     viewport.Model
-	messages     []ChatMessage
-	agentProcess *exec.Cmd
-	agentStdin   io.WriteCloser
-	agentStdout  io.ReadCloser
-	agentReady   bool
-	width        int
-	height       int
-	err          error
-	isProcessing bool
-	workDir      string // Store the working directory
+	state         AppState
+	providerIndex int
+	baseURLInput  textinput.Model
+	apiKeyInput   textinput.Model
+	chatInput     textarea.Model
+	viewport      viewport.Model
+	messages      []ChatMessage
+	client        *AgentClient
+	agentReady    bool
+	width         int
+	height        int
+	err           error
+	isProcessing  bool
+	workDir       string // Store the working directory
+	config        Config
+	activeConv    *Conversation
+	editSourceID  string // ParentID to branch from on next send; "" means append to head
+	editSelectID  string // ChatMessage.ID of the user message Ctrl+E targets; "" means the last one
+	streamingID   string // StoredMessage ID receiving in-progress MsgStreamChunk content
+	convSummaries []ConversationSummary
+	convCursor    int
+	pendingTool   *ToolCallRequest
+	sessionTools  map[string]bool // tools approved for the rest of this session
+
+	agents         []Agent
+	agentIndex     int
+	selectedAgent  *Agent
+	cliAgentName   string // preselected via -a/--agent, skips StateAgentSelect
+	pendingAPIKey  string
+	pendingBaseURL string
+
+	renderer      *glamour.TermRenderer
+	rendererWidth int
 }
 
 // Styles
@@ -123,15 +326,25 @@ type errMsg struct {
 	err error
 }
 
-func initialModel() Model {
+func initialModel(cliAgentName string) Model {
+	cfg := loadConfig()
+
 	// API Key input
 	ti := textinput.New()
-	ti.Placeholder = "Gemini API key"
-	ti.Focus()
+	ti.Placeholder = "API key"
 	ti.CharLimit = 200 // Increased to handle longer keys
 	ti.Width = 80      // Increased width to show more of the key
 	ti.EchoMode = textinput.EchoPassword
 
+	// Base URL input (shown for providers like Ollama that skip the key prompt)
+	bu := textinput.New()
+	bu.Placeholder = "http://localhost:11434"
+	bu.CharLimit = 200
+	bu.Width = 80
+	if cfg.LastBaseURL != "" {
+		bu.SetValue(cfg.LastBaseURL)
+	}
+
 	// Chat input
 	ta := textarea.New()
 	ta.Placeholder = "Type your message..."
@@ -141,7 +354,7 @@ func initialModel() Model {
 
 	// Viewport for messages
 	vp := viewport.New(80, 20)
-	vp.SetContent("Welcome to Keploy Agent!\n\nPlease enter your Google API key to begin.")
+	vp.SetContent("Welcome to Keploy Agent!\n\nSelect a model provider to begin.")
 
 	workDir := os.Getenv("KEPLOY_WORK_DIR")
 	if workDir == "" {
@@ -152,14 +365,29 @@ func initialModel() Model {
 		}
 	}
 
+	providerIndex := 0
+	for i, p := range Providers {
+		if p.Name() == cfg.LastProvider {
+			providerIndex = i
+			break
+		}
+	}
+
 	return Model{
-		state:       StateAPIKey,
-		apiKeyInput: ti,
-		chatInput:   ta,
-		viewport:    vp,
-		messages:    []ChatMessage{},
-		agentReady:  false,
-		workDir:     workDir,
+		state:         StateProviderSelect,
+		providerIndex: providerIndex,
+		baseURLInput:  bu,
+		apiKeyInput:   ti,
+		chatInput:     ta,
+		viewport:      vp,
+		messages:      []ChatMessage{},
+		client:        NewAgentClient(),
+		agentReady:    false,
+		workDir:       workDir,
+		config:        cfg,
+		sessionTools:  map[string]bool{},
+		agents:        loadAgents(),
+		cliAgentName:  cliAgentName,
 	}
 }
 
@@ -167,112 +395,164 @@ func (m Model) Init() tea.Cmd {
 	return textinput.Blink
 }
 
-type agentStartedMsg struct {
-	process *exec.Cmd
-	stdin   io.WriteCloser
-	stdout  io.ReadCloser
-}
+type agentStartedMsg struct{}
 
-func (m Model) startAgent(apiKey string) tea.Cmd {
+func (m Model) startAgent(provider Provider, apiKey, baseURL string, agent Agent) tea.Cmd {
 	return func() tea.Msg {
-		// Check if agent directory exists
-		if _, err := os.Stat("./agent"); os.IsNotExist(err) {
-			return errMsg{err: fmt.Errorf("agent directory not found. Please run from the keploy-agent directory")}
+		if err := m.client.Start(provider, apiKey, baseURL, agent, m.workDir); err != nil {
+			return errMsg{err: err}
 		}
+		return agentStartedMsg{}
+	}
+}
 
-		// Start the TypeScript agent process
-		cmd := exec.Command("npm", "start")
-		cmd.Dir = "./agent"
-
-		// Set up pipes
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			return errMsg{err: fmt.Errorf("failed to create stdin pipe: %w", err)}
+// proceedAfterCredentials moves on from the API key / base URL prompt.
+// If an agent was preselected via -a/--agent it starts the agent process
+// immediately; otherwise it stashes the credentials and shows the
+// StateAgentSelect picker.
+func (m *Model) proceedAfterCredentials(apiKey, baseURL string) tea.Cmd {
+	if m.cliAgentName != "" {
+		if agent, ok := agentByName(m.agents, m.cliAgentName); ok {
+			m.selectedAgent = &agent
+			m.isProcessing = true
+			return m.startAgent(Providers[m.providerIndex], apiKey, baseURL, agent)
 		}
+	}
 
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return errMsg{err: fmt.Errorf("failed to create stdout pipe: %w", err)}
-		}
+	m.pendingAPIKey = apiKey
+	m.pendingBaseURL = baseURL
+	m.agentIndex = 0
+	m.state = StateAgentSelect
+	return nil
+}
 
-		// Redirect stderr to a file for debugging
-		errFile, err := os.Create("agent-error.log")
-		if err == nil {
-			cmd.Stderr = errFile
+func (m Model) listenToAgent() tea.Cmd {
+	return func() tea.Msg {
+		msg, err := m.client.Next()
+		if err != nil {
+			if err == io.EOF {
+				return errMsg{err: fmt.Errorf("agent stream closed unexpectedly")}
+			}
+			return errMsg{err: err}
 		}
+		// The Update function will call listenToAgent again to continue
+		return agentResponseMsg{message: msg}
+	}
+}
 
-		// Start the process
-		if err := cmd.Start(); err != nil {
-			return errMsg{err: fmt.Errorf("failed to start agent: %w", err)}
-		}
+// appendUserMessage records a user message in the active conversation
+// (forking from editSourceID if a branch edit is pending), persists the
+// conversation, and refreshes the rendered message list.
+func (m *Model) appendUserMessage(message string) {
+	if m.activeConv == nil {
+		m.activeConv = newConversation()
+	}
 
-		// Send initialization message
-		initMsg := AgentMessage{
-			Type: MsgInit,
-			Data: json.RawMessage(fmt.Sprintf(`{"apiKey":"%s"}`, apiKey)),
-		}
+	parentID := m.activeConv.HeadID
+	if m.editSourceID != "" {
+		parentID = m.editSourceID
+		m.editSourceID = ""
+	}
 
-		msgBytes, _ := json.Marshal(initMsg)
-		stdin.Write(msgBytes)
-		stdin.Write([]byte("\n"))
+	m.activeConv.AddMessage(parentID, "user", message, false)
+	m.messages = chatMessagesFromConversation(m.activeConv, m.messages)
+	saveConversation(m.activeConv)
+}
 
-		// Return message with the process info
-		return agentStartedMsg{
-			process: cmd,
-			stdin:   stdin,
-			stdout:  stdout,
-		}
+// recordMessage appends a tool or system line to the active conversation
+// tree (creating one if chat hasn't started yet) and refreshes the
+// rendered message list from it. Every line shown in the transcript goes
+// through here or appendUserMessage so chatMessagesFromConversation's
+// wholesale rebuild from the tree never drops it. The conversation isn't
+// persisted until it has a title (i.e. a real user message has arrived),
+// so pre-chat system lines like the init banner don't litter the
+// conversations directory with blank, un-quittable-away entries.
+func (m *Model) recordMessage(role, content string, isError bool) {
+	if m.activeConv == nil {
+		m.activeConv = newConversation()
+	}
+	m.activeConv.AddMessage(m.activeConv.HeadID, role, content, isError)
+	m.messages = chatMessagesFromConversation(m.activeConv, m.messages)
+	if m.activeConv.Title != "" {
+		saveConversation(m.activeConv)
 	}
 }
 
-func (m Model) listenToAgent() tea.Cmd {
-	return func() tea.Msg {
-		if m.agentStdout == nil {
-			return errMsg{err: fmt.Errorf("agent stdout is nil")}
+// userMessageIndices returns the indices of m.messages holding "user"
+// role entries, in display order.
+func (m *Model) userMessageIndices() []int {
+	var idxs []int
+	for i, msg := range m.messages {
+		if msg.Role == "user" {
+			idxs = append(idxs, i)
 		}
+	}
+	return idxs
+}
 
-		scanner := bufio.NewScanner(m.agentStdout)
-		// Process ONE message and return it
-		// The Update function will call listenToAgent again to continue
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
-				continue
-			}
+// selectedEditTarget returns the m.messages index Ctrl+E should edit:
+// the user message matching editSelectID, or the last user message if
+// nothing has been explicitly selected. ok is false if there's no user
+// message to edit at all.
+func (m *Model) selectedEditTarget() (int, bool) {
+	idxs := m.userMessageIndices()
+	if len(idxs) == 0 {
+		return 0, false
+	}
 
-			var msg AgentMessage
-			if err := json.Unmarshal([]byte(line), &msg); err != nil {
-				continue
+	if m.editSelectID != "" {
+		for _, idx := range idxs {
+			if m.messages[idx].ID == m.editSelectID {
+				return idx, true
 			}
-
-			// Return this message and let Update reschedule listening
-			return agentResponseMsg{message: msg}
 		}
+	}
+	return idxs[len(idxs)-1], true
+}
 
-		if err := scanner.Err(); err != nil {
-			return errMsg{err: fmt.Errorf("agent stream error: %w", err)}
+// moveEditSelection shifts the Ctrl+E edit target by delta positions
+// among the conversation's user messages (Alt+↑ = earlier, Alt+↓ =
+// later). Moving past the most recent message clears editSelectID,
+// returning to the "edit the last message" default.
+func (m *Model) moveEditSelection(delta int) {
+	idxs := m.userMessageIndices()
+	if len(idxs) == 0 {
+		return
+	}
+
+	cur := len(idxs) - 1
+	for i, idx := range idxs {
+		if m.messages[idx].ID == m.editSelectID {
+			cur = i
+			break
 		}
+	}
 
-		// Agent closed the stream
-		return errMsg{err: fmt.Errorf("agent stream closed unexpectedly")}
+	cur += delta
+	if cur < 0 {
+		cur = 0
 	}
+	if cur >= len(idxs) {
+		m.editSelectID = ""
+		return
+	}
+	m.editSelectID = m.messages[idxs[cur]].ID
 }
 
 func (m Model) sendChatMessage(message string) tea.Cmd {
 	return func() tea.Msg {
-		if m.agentStdin == nil {
-			return errMsg{err: fmt.Errorf("agent not initialized")}
+		if err := m.client.SendChat(message); err != nil {
+			return errMsg{err: err}
 		}
+		return nil
+	}
+}
 
-		chatMsg := AgentMessage{
-			Type: MsgChat,
-			Data: json.RawMessage(fmt.Sprintf(`{"message":"%s"}`, strings.ReplaceAll(message, "\"", "\\\""))),
+func (m Model) sendToolDecision(id, decision string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.client.SendToolDecision(id, decision); err != nil {
+			return errMsg{err: err}
 		}
-
-		msgBytes, _ := json.Marshal(chatMsg)
-		m.agentStdin.Write(msgBytes)
-		m.agentStdin.Write([]byte("\n"))
-
 		return nil
 	}
 }
@@ -282,30 +562,92 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.pendingTool != nil {
+			return m.handleToolConfirmKey(msg)
+		}
+
 		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
-			// Clean shutdown
-			if m.agentProcess != nil {
-				m.agentProcess.Process.Kill()
+		case tea.KeyEsc:
+			if m.state == StateConversationList {
+				m.state = StateChat
+				break
 			}
+			fallthrough
+
+		case tea.KeyCtrlC:
+			// Clean shutdown
+			m.client.Kill()
 			return m, tea.Quit
 
+		case tea.KeyUp:
+			if m.state == StateChat && msg.Alt {
+				m.moveEditSelection(-1)
+				m.updateViewport()
+			} else if m.state == StateProviderSelect && m.providerIndex > 0 {
+				m.providerIndex--
+			} else if m.state == StateAgentSelect && m.agentIndex > 0 {
+				m.agentIndex--
+			} else if m.state == StateConversationList && m.convCursor > 0 {
+				m.convCursor--
+			}
+
+		case tea.KeyDown:
+			if m.state == StateChat && msg.Alt {
+				m.moveEditSelection(1)
+				m.updateViewport()
+			} else if m.state == StateProviderSelect && m.providerIndex < len(Providers)-1 {
+				m.providerIndex++
+			} else if m.state == StateAgentSelect && m.agentIndex < len(m.agents)-1 {
+				m.agentIndex++
+			} else if m.state == StateConversationList && m.convCursor < len(m.convSummaries)-1 {
+				m.convCursor++
+			}
+
 		case tea.KeyEnter:
-			if m.state == StateAPIKey && !m.isProcessing {
+			if m.state == StateProviderSelect && !m.isProcessing {
+				provider := Providers[m.providerIndex]
+				m.config.LastProvider = provider.Name()
+				saveConfig(m.config)
+				if provider.RequiresAPIKey() {
+					m.state = StateAPIKey
+					m.apiKeyInput.Placeholder = provider.Label() + " API key"
+					m.apiKeyInput.Focus()
+				} else {
+					m.state = StateBaseURL
+					m.baseURLInput.Focus()
+				}
+			} else if m.state == StateAPIKey && !m.isProcessing {
 				apiKey := m.apiKeyInput.Value()
 				if apiKey != "" {
-					m.isProcessing = true
-					cmds = append(cmds, m.startAgent(apiKey))
+					cmds = append(cmds, m.proceedAfterCredentials(apiKey, ""))
+				}
+			} else if m.state == StateBaseURL && !m.isProcessing {
+				baseURL := strings.TrimSpace(m.baseURLInput.Value())
+				if baseURL != "" {
+					m.config.LastBaseURL = baseURL
+					saveConfig(m.config)
+					cmds = append(cmds, m.proceedAfterCredentials("", baseURL))
+				}
+			} else if m.state == StateAgentSelect && !m.isProcessing {
+				agent := m.agents[m.agentIndex]
+				m.selectedAgent = &agent
+				m.isProcessing = true
+				cmds = append(cmds, m.startAgent(Providers[m.providerIndex], m.pendingAPIKey, m.pendingBaseURL, agent))
+			} else if m.state == StateConversationList && len(m.convSummaries) > 0 && !m.isProcessing {
+				loaded, err := loadConversation(m.convSummaries[m.convCursor].ID)
+				if err == nil {
+					m.activeConv = loaded
+					m.messages = chatMessagesFromConversation(loaded, m.messages)
+					m.editSelectID = ""
+					m.state = StateChat
+					m.updateViewport()
 				}
 			} else if m.state == StateChat && !strings.Contains(m.chatInput.Value(), "\n") {
 				// Send message on Enter if not in multiline mode (no newlines present)
 				message := strings.TrimSpace(m.chatInput.Value())
 				if message != "" && m.agentReady && !m.isProcessing {
-					m.messages = append(m.messages, ChatMessage{
-						Role:      "user",
-						Content:   message,
-						Timestamp: time.Now(),
-					})
+					m.editSelectID = ""
+					m.appendUserMessage(message)
 					m.chatInput.Reset()
 					m.isProcessing = true
 					m.updateViewport()
@@ -318,17 +660,55 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.state == StateChat {
 				message := strings.TrimSpace(m.chatInput.Value())
 				if message != "" && m.agentReady && !m.isProcessing {
-					m.messages = append(m.messages, ChatMessage{
-						Role:      "user",
-						Content:   message,
-						Timestamp: time.Now(),
-					})
+					m.editSelectID = ""
+					m.appendUserMessage(message)
 					m.chatInput.Reset()
 					m.isProcessing = true
 					m.updateViewport()
 					cmds = append(cmds, m.sendChatMessage(message))
 				}
 			}
+
+		case tea.KeyCtrlN:
+			// Start a fresh conversation
+			if m.state == StateChat && !m.isProcessing {
+				m.activeConv = newConversation()
+				m.messages = []ChatMessage{}
+				m.editSourceID = ""
+				m.editSelectID = ""
+				m.updateViewport()
+			}
+
+		case tea.KeyCtrlO:
+			// Open the conversation list
+			if m.state == StateChat && !m.isProcessing {
+				summaries, _ := listConversations()
+				m.convSummaries = summaries
+				m.convCursor = 0
+				m.state = StateConversationList
+			}
+
+		case tea.KeyCtrlD:
+			// Delete the selected conversation
+			if m.state == StateConversationList && len(m.convSummaries) > 0 {
+				deleteConversation(m.convSummaries[m.convCursor].ID)
+				m.convSummaries = append(m.convSummaries[:m.convCursor], m.convSummaries[m.convCursor+1:]...)
+				if m.convCursor >= len(m.convSummaries) && m.convCursor > 0 {
+					m.convCursor--
+				}
+			}
+
+		case tea.KeyCtrlE:
+			// Edit the selected user message (Alt+↑/↓ to pick one, defaults
+			// to the last) and re-prompt, forking the conversation from it.
+			if m.state == StateChat && m.activeConv != nil {
+				if idx, ok := m.selectedEditTarget(); ok {
+					if parent, ok := m.activeConv.Messages[m.messages[idx].ID]; ok {
+						m.editSourceID = parent.ParentID
+						m.chatInput.SetValue(m.messages[idx].Content)
+					}
+				}
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -347,12 +727,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateViewport()
 
 	case agentStartedMsg:
-		// Agent process started successfully
-		m.agentProcess = msg.process
-		m.agentStdin = msg.stdin
-		m.agentStdout = msg.stdout
-
-		// Start listening to agent output
+		// Agent process started successfully; begin listening to its output
 		cmds = append(cmds, m.listenToAgent())
 
 	case agentResponseMsg:
@@ -373,31 +748,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.agentReady = true
 				m.isProcessing = false
 				m.chatInput.Focus()
-				m.messages = append(m.messages, ChatMessage{
-					Role:      "system",
-					Content:   "✓ Agent initialized successfully! You can now start by sending a message.",
-					Timestamp: time.Now(),
-				})
+				m.recordMessage("system", "✓ Agent initialized successfully! You can now start by sending a message.", false)
 			} else if respData.Content != "" {
-				// Check if we already have this content from streaming
-				// If the last message is from assistant with the same content, don't duplicate
-				if len(m.messages) > 0 &&
-					m.messages[len(m.messages)-1].Role == "assistant" &&
-					m.messages[len(m.messages)-1].Content == respData.Content {
-					// Already have this content from streaming, just update processing state
-					m.isProcessing = false
-				} else if len(m.messages) == 0 || m.messages[len(m.messages)-1].Role != "assistant" {
-					// No assistant message yet, add it
-					m.messages = append(m.messages, ChatMessage{
-						Role:      "assistant",
-						Content:   respData.Content,
-						Timestamp: time.Now(),
-					})
-					m.isProcessing = false
-				} else {
-					// Just mark as done
-					m.isProcessing = false
+				if m.activeConv != nil {
+					if m.streamingID != "" {
+						// The streamed assistant message already exists; finalize its content.
+						if sm, ok := m.activeConv.Messages[m.streamingID]; ok {
+							sm.Content = respData.Content
+							m.activeConv.UpdatedAt = time.Now()
+						}
+						m.streamingID = ""
+					} else {
+						m.activeConv.AddMessage(m.activeConv.HeadID, "assistant", respData.Content, false)
+					}
+					if m.activeConv.Title != "" {
+						saveConversation(m.activeConv)
+					}
+					m.messages = chatMessagesFromConversation(m.activeConv, m.messages)
 				}
+				m.isProcessing = false
 			}
 			m.updateViewport()
 
@@ -405,121 +774,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			var toolData ToolCallInfo
 			json.Unmarshal(msg.message.Data, &toolData)
 
-			// Create a concise single-line format for tool calls
-			// Format: "Tool: tool_name | param1: value1, param2: value2"
-			toolMsg := fmt.Sprintf("🔧 Tool: %s", toolData.ToolName)
+			m.recordMessage("tool", formatToolCallSummary(toolData.ToolName, toolData.Args), false)
+			m.updateViewport()
 
-			// Add key parameters in a compact format
-			if len(toolData.Args) > 0 {
-				var params []string
+		case MsgToolCallRequest:
+			var req ToolCallRequest
+			json.Unmarshal(msg.message.Data, &req)
 
-				// Special handling for common tools to show most relevant info
-				switch toolData.ToolName {
-				case "read_file", "write_file", "edit_file":
-					if filePath, ok := toolData.Args["filePath"].(string); ok {
-						params = append(params, fmt.Sprintf("file: %s", filePath))
-					}
-				case "list_files":
-					if dirPath, ok := toolData.Args["dirPath"].(string); ok {
-						params = append(params, fmt.Sprintf("dir: %s", dirPath))
-					}
-					if recursive, ok := toolData.Args["recursive"].(bool); ok && recursive {
-						params = append(params, "recursive")
-					}
-				case "search_files":
-					if pattern, ok := toolData.Args["pattern"].(string); ok {
-						params = append(params, fmt.Sprintf("pattern: \"%s\"", pattern))
-					}
-					if dir, ok := toolData.Args["directory"].(string); ok && dir != "." {
-						params = append(params, fmt.Sprintf("in: %s", dir))
-					}
-				case "run_command":
-					if cmd, ok := toolData.Args["command"].(string); ok {
-						// Truncate long commands
-						if len(cmd) > 50 {
-							params = append(params, fmt.Sprintf("cmd: %s...", cmd[:50]))
-						} else {
-							params = append(params, fmt.Sprintf("cmd: %s", cmd))
-						}
-					}
-				case "web_search":
-					if query, ok := toolData.Args["query"].(string); ok {
-						params = append(params, fmt.Sprintf("query: \"%s\"", query))
-					}
-					if limit, ok := toolData.Args["limit"].(float64); ok && limit != 3 {
-						params = append(params, fmt.Sprintf("limit: %d", int(limit)))
-					}
-					if scrape, ok := toolData.Args["scrape"].(bool); ok && scrape {
-						params = append(params, "scrape: true")
-					}
-				case "url_extract":
-					if url, ok := toolData.Args["url"].(string); ok {
-						// Truncate long URLs
-						if len(url) > 50 {
-							params = append(params, fmt.Sprintf("url: %s...", url[:50]))
-						} else {
-							params = append(params, fmt.Sprintf("url: %s", url))
-						}
-					}
-					if formats, ok := toolData.Args["formats"].([]interface{}); ok && len(formats) > 0 {
-						formatStrs := make([]string, 0)
-						for _, f := range formats {
-							if fStr, ok := f.(string); ok {
-								formatStrs = append(formatStrs, fStr)
-							}
-						}
-						if len(formatStrs) > 0 {
-							params = append(params, fmt.Sprintf("formats: [%s]", strings.Join(formatStrs, ",")))
-						}
-					}
-				case "generate_unit_tests":
-					if filePath, ok := toolData.Args["filePath"].(string); ok {
-						params = append(params, fmt.Sprintf("file: %s", filePath))
-					}
-					if testFramework, ok := toolData.Args["testFramework"].(string); ok && testFramework != "testing" {
-						params = append(params, fmt.Sprintf("framework: %s", testFramework))
-					}
-					if coverageTarget, ok := toolData.Args["coverageTarget"].(float64); ok {
-						params = append(params, fmt.Sprintf("coverage: %d%%", int(coverageTarget)))
-					}
-				default:
-					// Generic handling for unknown tools
-					for key, value := range toolData.Args {
-						var valueStr string
-						switch v := value.(type) {
-						case string:
-							if len(v) > 30 {
-								valueStr = fmt.Sprintf("\"%s...\"", v[:30])
-							} else {
-								valueStr = fmt.Sprintf("\"%s\"", v)
-							}
-						case bool:
-							valueStr = fmt.Sprintf("%v", v)
-						case float64:
-							if v == float64(int(v)) {
-								valueStr = fmt.Sprintf("%d", int(v))
-							} else {
-								valueStr = fmt.Sprintf("%v", v)
-							}
-						default:
-							valueStr = fmt.Sprintf("%v", v)
-						}
-						params = append(params, fmt.Sprintf("%s: %s", key, valueStr))
-					}
-				}
-
-				if len(params) > 0 {
-					toolMsg += " | " + strings.Join(params, ", ")
-				}
+			if !isAlwaysGated(req.ToolName) && (m.config.AutoApproveTools[req.ToolName] || m.sessionTools[req.ToolName]) {
+				m.recordMessage("tool", formatToolCallSummary(req.ToolName, req.Args), false)
+				m.updateViewport()
+				cmds = append(cmds, m.sendToolDecision(req.ID, DecisionApprove))
+			} else {
+				m.pendingTool = &req
 			}
 
-			m.messages = append(m.messages, ChatMessage{
-				Role:      "tool",
-				Content:   toolMsg,
-				Timestamp: time.Now(),
-			})
-			m.updateViewport()
-
 		case MsgStreamChunk:
 			var chunkData struct {
 				Content string `json:"content"`
@@ -529,22 +798,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			if chunkData.Status == "thinking" {
 				// Show thinking indicator
-				m.messages = append(m.messages, ChatMessage{
-					Role:      "system",
-					Content:   "💭 Thinking...",
-					Timestamp: time.Now(),
-				})
-			} else if chunkData.Content != "" {
-				// Update or append assistant message
-				if len(m.messages) > 0 && m.messages[len(m.messages)-1].Role == "assistant" {
-					m.messages[len(m.messages)-1].Content = chunkData.Content
+				m.recordMessage("system", "💭 Thinking...", false)
+			} else if chunkData.Content != "" && m.activeConv != nil {
+				// Update or append the in-progress assistant message
+				if m.streamingID != "" {
+					if sm, ok := m.activeConv.Messages[m.streamingID]; ok {
+						sm.Content = chunkData.Content
+					}
 				} else {
-					m.messages = append(m.messages, ChatMessage{
-						Role:      "assistant",
-						Content:   chunkData.Content,
-						Timestamp: time.Now(),
-					})
+					sm := m.activeConv.AddMessage(m.activeConv.HeadID, "assistant", chunkData.Content, false)
+					m.streamingID = sm.ID
 				}
+				m.messages = chatMessagesFromConversation(m.activeConv, m.messages)
 			}
 			m.updateViewport()
 
@@ -555,12 +820,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			json.Unmarshal(msg.message.Data, &errData)
 
-			m.messages = append(m.messages, ChatMessage{
-				Role:      "system",
-				Content:   fmt.Sprintf("❌ Error: %s", errData.Message),
-				Timestamp: time.Now(),
-				IsError:   true,
-			})
+			m.recordMessage("system", fmt.Sprintf("❌ Error: %s", errData.Message), true)
 			m.isProcessing = false
 			m.updateViewport()
 		}
@@ -568,12 +828,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case errMsg:
 		m.err = msg.err
 		m.isProcessing = false
-		m.messages = append(m.messages, ChatMessage{
-			Role:      "system",
-			Content:   fmt.Sprintf("❌ System Error: %s", msg.err.Error()),
-			Timestamp: time.Now(),
-			IsError:   true,
-		})
+		m.recordMessage("system", fmt.Sprintf("❌ System Error: %s", msg.err.Error()), true)
 		m.updateViewport()
 	}
 
@@ -582,6 +837,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.apiKeyInput, cmd = m.apiKeyInput.Update(msg)
 		cmds = append(cmds, cmd)
+	} else if m.state == StateBaseURL {
+		var cmd tea.Cmd
+		m.baseURLInput, cmd = m.baseURLInput.Update(msg)
+		cmds = append(cmds, cmd)
 	} else if m.state == StateChat {
 		var cmd tea.Cmd
 		m.chatInput, cmd = m.chatInput.Update(msg)
@@ -594,10 +853,79 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// handleToolConfirmKey processes y/n/a/s while a tool call confirmation
+// modal is focused, replying to the agent with the corresponding
+// ToolCallDecision and recording the call in the transcript once approved.
+func (m Model) handleToolConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	req := m.pendingTool
+
+	approve := func(decision string) (tea.Model, tea.Cmd) {
+		m.pendingTool = nil
+		m.recordMessage("tool", formatToolCallSummary(req.ToolName, req.Args), false)
+		m.updateViewport()
+		return m, m.sendToolDecision(req.ID, decision)
+	}
+
+	switch msg.String() {
+	case "y":
+		return approve(DecisionApprove)
+
+	case "a":
+		if isAlwaysGated(req.ToolName) {
+			// Always-gated tools never get a standing approval - the
+			// agent must be told the truth, not just have the local
+			// bookkeeping skipped.
+			return approve(DecisionApprove)
+		}
+		m.sessionTools[req.ToolName] = true
+		return approve(DecisionApproveAlwaysInSession)
+
+	case "s":
+		if isAlwaysGated(req.ToolName) {
+			return approve(DecisionApprove)
+		}
+		if m.config.AutoApproveTools == nil {
+			m.config.AutoApproveTools = map[string]bool{}
+		}
+		m.config.AutoApproveTools[req.ToolName] = true
+		saveConfig(m.config)
+		return approve(DecisionApproveAlwaysForTool)
+
+	case "n", "esc", "ctrl+c":
+		m.pendingTool = nil
+		return m, m.sendToolDecision(req.ID, DecisionDeny)
+	}
+
+	return m, nil
+}
+
+// ensureRenderer (re)builds the glamour markdown renderer sized to the
+// viewport so wrapping matches the current terminal width. It's a no-op
+// if the width hasn't changed since the last build.
+func (m *Model) ensureRenderer(width int) {
+	if width <= 0 || (m.renderer != nil && m.rendererWidth == width) {
+		return
+	}
+
+	r, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(width),
+	)
+	if err != nil {
+		return
+	}
+
+	m.renderer = r
+	m.rendererWidth = width
+}
+
 func (m *Model) updateViewport() {
+	m.ensureRenderer(m.viewport.Width - 4)
+
 	var content strings.Builder
 
-	for _, msg := range m.messages {
+	for i := range m.messages {
+		msg := &m.messages[i]
 		timestamp := msg.Timestamp.Format("15:04:05")
 
 		var style lipgloss.Style
@@ -607,6 +935,9 @@ func (m *Model) updateViewport() {
 		case "user":
 			style = userMsgStyle
 			prefix = "You"
+			if msg.ID != "" && msg.ID == m.editSelectID {
+				prefix = "You ✎ (Ctrl+E target)"
+			}
 		case "assistant":
 			style = assistantMsgStyle
 			prefix = "AI"
@@ -622,15 +953,19 @@ func (m *Model) updateViewport() {
 			prefix = "System"
 		}
 
-		// Format based on role
-		if msg.Role == "tool" {
+		switch msg.Role {
+		case "tool":
 			// Tool messages get a compact single-line format
 			content.WriteString(fmt.Sprintf("[%s] %s\n", timestamp, toolMsgStyle.Render(msg.Content)))
-		} else {
-			// Regular messages with prefix and indentation
+		case "assistant":
+			// Markdown-rendered, syntax-highlighted content
+			content.WriteString(fmt.Sprintf("[%s] %s:\n", timestamp, style.Render(prefix)))
+			content.WriteString(msg.rendered(m.renderer, m.rendererWidth))
+			content.WriteString("\n")
+		default:
+			// User and system messages stay plain to keep the compact look
 			content.WriteString(fmt.Sprintf("[%s] %s:\n", timestamp, style.Render(prefix)))
 
-			// Wrap and indent message content
 			lines := strings.Split(msg.Content, "\n")
 			for _, line := range lines {
 				content.WriteString(fmt.Sprintf("  %s\n", line))
@@ -643,18 +978,85 @@ func (m *Model) updateViewport() {
 	m.viewport.GotoBottom()
 }
 
+// renderToolConfirmModal shows the pending tool call's name and
+// pretty-printed arguments, gated behind y/n/a/s.
+func (m Model) renderToolConfirmModal() string {
+	req := m.pendingTool
+
+	title := titleStyle.Render("⚠️  Tool Call Confirmation")
+	name := fmt.Sprintf("Tool: %s", toolMsgStyle.Render(req.ToolName))
+
+	var body string
+	if req.ToolName == "run_command" {
+		if cmd, ok := req.Args["command"].(string); ok {
+			body = fmt.Sprintf("Command:\n  %s", cmd)
+		}
+	}
+	if body == "" {
+		argsJSON, _ := json.MarshalIndent(req.Args, "", "  ")
+		body = fmt.Sprintf("Arguments:\n%s", string(argsJSON))
+	}
+
+	helpText := "\n[y] approve  [n] deny"
+	if !isAlwaysGated(req.ToolName) {
+		helpText += "  [a] always this session  [s] always for this tool"
+	}
+	help := helpStyle.Render(helpText)
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		name,
+		"",
+		body,
+		help,
+	)
+
+	return inputStyle.Render(content)
+}
+
 func (m Model) View() string {
 	if m.width == 0 {
 		return "Initializing..."
 	}
 
+	if m.pendingTool != nil {
+		return appStyle.Render(m.renderToolConfirmModal())
+	}
+
 	var content string
 
 	switch m.state {
+	case StateProviderSelect:
+		title := titleStyle.Render("🚀 Keploy Agent")
+
+		var lines []string
+		for i, p := range Providers {
+			cursor := "  "
+			style := statusStyle
+			if i == m.providerIndex {
+				cursor = "> "
+				style = userMsgStyle
+			}
+			lines = append(lines, cursor+style.Render(p.Label()))
+		}
+
+		help := helpStyle.Render("\n↑/↓ to choose • Enter to continue • Ctrl+C to quit")
+
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			"",
+			"Select a model provider:",
+			strings.Join(lines, "\n"),
+			help,
+		)
+
 	case StateAPIKey:
 		title := titleStyle.Render("🚀 Keploy Agent")
 
-		prompt := "Google API key:"
+		prompt := Providers[m.providerIndex].Label() + " API key:"
 		if m.isProcessing {
 			prompt = "Initializing agent..."
 		}
@@ -676,6 +1078,61 @@ func (m Model) View() string {
 			content += "\n\n" + errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
 		}
 
+	case StateBaseURL:
+		title := titleStyle.Render("🚀 Keploy Agent")
+
+		prompt := Providers[m.providerIndex].Label() + " base URL:"
+		if m.isProcessing {
+			prompt = "Initializing agent..."
+		}
+
+		input := inputStyle.Render(m.baseURLInput.View())
+
+		help := helpStyle.Render("\nPress Enter to continue • Ctrl+C to quit")
+
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			"",
+			prompt,
+			input,
+			help,
+		)
+
+		if m.err != nil {
+			content += "\n\n" + errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
+		}
+
+	case StateAgentSelect:
+		title := titleStyle.Render("🚀 Keploy Agent")
+
+		var lines []string
+		for i, a := range m.agents {
+			cursor := "  "
+			style := statusStyle
+			if i == m.agentIndex {
+				cursor = "> "
+				style = userMsgStyle
+			}
+			lines = append(lines, cursor+style.Render(a.Name)+"  "+helpStyle.Render(strings.Join(a.Tools, ", ")))
+		}
+
+		prompt := "Select an agent profile:"
+		if m.isProcessing {
+			prompt = "Initializing agent..."
+		}
+
+		help := helpStyle.Render("\n↑/↓ to choose • Enter to continue • Ctrl+C to quit")
+
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			"",
+			prompt,
+			strings.Join(lines, "\n"),
+			help,
+		)
+
 	case StateChat:
 		title := titleStyle.Render("💬 Keploy Assistant")
 
@@ -704,7 +1161,7 @@ func (m Model) View() string {
 			inputStyle.Render(m.chatInput.View()),
 		)
 
-		help := helpStyle.Render("Ctrl+S to send • Ctrl+C to quit")
+		help := helpStyle.Render("Ctrl+S to send • Ctrl+N new • Ctrl+O open • Alt+↑/↓ select msg • Ctrl+E edit & re-prompt • Ctrl+C to quit")
 
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
@@ -713,12 +1170,98 @@ func (m Model) View() string {
 			chatInputView,
 			help,
 		)
+
+	case StateConversationList:
+		title := titleStyle.Render("🗂 Conversations")
+
+		var lines []string
+		if len(m.convSummaries) == 0 {
+			lines = append(lines, statusStyle.Render("No saved conversations yet."))
+		}
+		for i, s := range m.convSummaries {
+			cursor := "  "
+			style := statusStyle
+			if i == m.convCursor {
+				cursor = "> "
+				style = userMsgStyle
+			}
+			label := fmt.Sprintf("%s (%s)", s.Title, s.UpdatedAt.Format("2006-01-02 15:04"))
+			lines = append(lines, cursor+style.Render(label))
+		}
+
+		help := helpStyle.Render("\n↑/↓ to choose • Enter to open • Ctrl+D to delete • Esc to cancel")
+
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			"",
+			strings.Join(lines, "\n"),
+			help,
+		)
 	}
 
 	return appStyle.Render(content)
 }
 
+// isHeadlessInput reports whether stdin is piped rather than an
+// interactive terminal, e.g. `cat bug.log | keploy-agent`.
+func isHeadlessInput() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "prompt" {
+		promptFlags := flag.NewFlagSet("prompt", flag.ExitOnError)
+		agentName := promptFlags.String("a", "coder", "agent profile to use")
+		promptFlags.StringVar(agentName, "agent", "coder", "agent profile to use")
+		promptFlags.Parse(os.Args[2:])
+
+		message := strings.Join(promptFlags.Args(), " ")
+		if isHeadlessInput() {
+			if data, err := io.ReadAll(os.Stdin); err == nil {
+				if piped := strings.TrimSpace(string(data)); piped != "" {
+					if message != "" {
+						message = message + "\n\n" + piped
+					} else {
+						message = piped
+					}
+				}
+			}
+		}
+		if message == "" {
+			fmt.Fprintln(os.Stderr, `usage: keploy-agent prompt "<message>"`+" (or pipe input via stdin)")
+			os.Exit(1)
+		}
+
+		if err := runHeadlessPrompt(*agentName, message); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var agentName string
+	flag.StringVar(&agentName, "a", "", "name of the agent profile to use (see ~/.config/keploy-agent/agents)")
+	flag.StringVar(&agentName, "agent", "", "name of the agent profile to use (see ~/.config/keploy-agent/agents)")
+	flag.Parse()
+
+	if isHeadlessInput() {
+		data, err := io.ReadAll(os.Stdin)
+		if err == nil {
+			if message := strings.TrimSpace(string(data)); message != "" {
+				if err := runHeadlessPrompt(agentName, message); err != nil {
+					fmt.Fprintln(os.Stderr, "Error:", err)
+					os.Exit(1)
+				}
+				return
+			}
+		}
+	}
+
 	// Set up logging - try to create log file but don't fail if we can't
 	homeDir, _ := os.UserHomeDir()
 	logPath := filepath.Join(homeDir, ".local", "lib", "keploy-agent", "keploy-agent.log")
@@ -732,7 +1275,7 @@ func main() {
 	}
 
 	// Create and run the Keploy Agent
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+	p := tea.NewProgram(initialModel(agentName), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running Keploy Agent: %v\n", err)
 		os.Exit(1)
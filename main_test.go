@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+func testMessages(roles ...string) []ChatMessage {
+	msgs := make([]ChatMessage, len(roles))
+	for i, role := range roles {
+		msgs[i] = ChatMessage{ID: role, Role: role}
+	}
+	return msgs
+}
+
+func TestSelectedEditTargetDefaultsToLastUserMessage(t *testing.T) {
+	m := &Model{messages: []ChatMessage{
+		{ID: "u1", Role: "user"},
+		{ID: "a1", Role: "assistant"},
+		{ID: "u2", Role: "user"},
+	}}
+
+	idx, ok := m.selectedEditTarget()
+	if !ok {
+		t.Fatal("selectedEditTarget() ok = false, want true")
+	}
+	if m.messages[idx].ID != "u2" {
+		t.Fatalf("selectedEditTarget() = message %q, want u2", m.messages[idx].ID)
+	}
+}
+
+func TestSelectedEditTargetNoUserMessages(t *testing.T) {
+	m := &Model{messages: []ChatMessage{
+		{ID: "s1", Role: "system"},
+		{ID: "a1", Role: "assistant"},
+	}}
+
+	if _, ok := m.selectedEditTarget(); ok {
+		t.Fatal("selectedEditTarget() ok = true with no user messages, want false")
+	}
+}
+
+func TestSelectedEditTargetHonorsExplicitSelection(t *testing.T) {
+	m := &Model{
+		messages: []ChatMessage{
+			{ID: "u1", Role: "user"},
+			{ID: "a1", Role: "assistant"},
+			{ID: "u2", Role: "user"},
+		},
+		editSelectID: "u1",
+	}
+
+	idx, ok := m.selectedEditTarget()
+	if !ok || m.messages[idx].ID != "u1" {
+		t.Fatalf("selectedEditTarget() = (%d, %v), want the explicitly selected message u1", idx, ok)
+	}
+}
+
+func TestMoveEditSelectionWalksUserMessagesInOrder(t *testing.T) {
+	m := &Model{messages: []ChatMessage{
+		{ID: "u1", Role: "user"},
+		{ID: "a1", Role: "assistant"},
+		{ID: "u2", Role: "user"},
+		{ID: "a2", Role: "assistant"},
+		{ID: "u3", Role: "user"},
+	}}
+
+	// No explicit selection yet: one step earlier moves off the implicit
+	// "last message" default to the second-to-last user message.
+	m.moveEditSelection(-1)
+	if m.editSelectID != "u2" {
+		t.Fatalf("after moveEditSelection(-1), editSelectID = %q, want u2", m.editSelectID)
+	}
+
+	m.moveEditSelection(-1)
+	if m.editSelectID != "u1" {
+		t.Fatalf("after second moveEditSelection(-1), editSelectID = %q, want u1", m.editSelectID)
+	}
+
+	// Clamped at the earliest user message.
+	m.moveEditSelection(-1)
+	if m.editSelectID != "u1" {
+		t.Fatalf("moveEditSelection(-1) past the first message gave %q, want clamped at u1", m.editSelectID)
+	}
+
+	m.moveEditSelection(1)
+	if m.editSelectID != "u2" {
+		t.Fatalf("after moveEditSelection(1), editSelectID = %q, want u2", m.editSelectID)
+	}
+
+	// Moving past the most recent message clears the selection, falling
+	// back to the "edit the last message" default.
+	m.moveEditSelection(2)
+	if m.editSelectID != "" {
+		t.Fatalf("after moving past the last message, editSelectID = %q, want cleared", m.editSelectID)
+	}
+}
+
+func TestMoveEditSelectionNoUserMessagesIsNoop(t *testing.T) {
+	m := &Model{messages: []ChatMessage{{ID: "s1", Role: "system"}}}
+	m.moveEditSelection(-1)
+	if m.editSelectID != "" {
+		t.Fatalf("editSelectID = %q after moveEditSelection on a message list with no user messages, want empty", m.editSelectID)
+	}
+}
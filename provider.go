@@ -0,0 +1,94 @@
+package main
+
+// Provider describes a model-provider backend that the TypeScript agent
+// can route chat requests to. Each concrete provider knows which
+// environment variables it needs, what its default model is, and how to
+// build the payload that goes out as part of MsgInit.
+type Provider interface {
+	// Name is the stable identifier used in config files and MsgInit.
+	Name() string
+	// Label is the human-readable name shown in the TUI picker.
+	Label() string
+	// EnvVars returns the environment variables the downstream agent
+	// process should see for this provider, given the user-supplied key.
+	EnvVars(apiKey string) map[string]string
+	// InitPayload builds the provider-specific fields merged into the
+	// MsgInit message sent to the agent.
+	InitPayload(apiKey, baseURL, model string) map[string]interface{}
+	// RequiresAPIKey reports whether StateAPIKey should be shown for
+	// this provider. Ollama runs locally and only needs a base URL.
+	RequiresAPIKey() bool
+	// DefaultModel is used when the user hasn't picked one explicitly.
+	DefaultModel() string
+}
+
+type openAIProvider struct{}
+
+func (openAIProvider) Name() string  { return "openai" }
+func (openAIProvider) Label() string { return "OpenAI" }
+func (openAIProvider) EnvVars(apiKey string) map[string]string {
+	return map[string]string{"OPENAI_API_KEY": apiKey}
+}
+func (openAIProvider) InitPayload(apiKey, baseURL, model string) map[string]interface{} {
+	return map[string]interface{}{"provider": "openai", "apiKey": apiKey, "baseURL": baseURL, "model": model}
+}
+func (openAIProvider) RequiresAPIKey() bool { return true }
+func (openAIProvider) DefaultModel() string { return "gpt-4o" }
+
+type anthropicProvider struct{}
+
+func (anthropicProvider) Name() string  { return "anthropic" }
+func (anthropicProvider) Label() string { return "Anthropic" }
+func (anthropicProvider) EnvVars(apiKey string) map[string]string {
+	return map[string]string{"ANTHROPIC_API_KEY": apiKey}
+}
+func (anthropicProvider) InitPayload(apiKey, baseURL, model string) map[string]interface{} {
+	return map[string]interface{}{"provider": "anthropic", "apiKey": apiKey, "baseURL": baseURL, "model": model}
+}
+func (anthropicProvider) RequiresAPIKey() bool { return true }
+func (anthropicProvider) DefaultModel() string { return "claude-sonnet-4-5" }
+
+type googleProvider struct{}
+
+func (googleProvider) Name() string  { return "google" }
+func (googleProvider) Label() string { return "Google Gemini" }
+func (googleProvider) EnvVars(apiKey string) map[string]string {
+	return map[string]string{"GOOGLE_API_KEY": apiKey}
+}
+func (googleProvider) InitPayload(apiKey, baseURL, model string) map[string]interface{} {
+	return map[string]interface{}{"provider": "google", "apiKey": apiKey, "baseURL": baseURL, "model": model}
+}
+func (googleProvider) RequiresAPIKey() bool { return true }
+func (googleProvider) DefaultModel() string { return "gemini-2.0-flash" }
+
+type ollamaProvider struct{}
+
+func (ollamaProvider) Name() string  { return "ollama" }
+func (ollamaProvider) Label() string { return "Ollama (local)" }
+func (ollamaProvider) EnvVars(apiKey string) map[string]string {
+	return map[string]string{}
+}
+func (ollamaProvider) InitPayload(apiKey, baseURL, model string) map[string]interface{} {
+	return map[string]interface{}{"provider": "ollama", "apiKey": "", "baseURL": baseURL, "model": model}
+}
+func (ollamaProvider) RequiresAPIKey() bool { return false }
+func (ollamaProvider) DefaultModel() string { return "llama3.1" }
+
+// Providers lists the available backends in picker order.
+var Providers = []Provider{
+	googleProvider{},
+	openAIProvider{},
+	anthropicProvider{},
+	ollamaProvider{},
+}
+
+// ProviderByName looks up a provider by its Name(), returning the
+// default (Google) provider if name is unknown or empty.
+func ProviderByName(name string) Provider {
+	for _, p := range Providers {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return Providers[0]
+}
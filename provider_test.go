@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestProviderByNameKnownProviders(t *testing.T) {
+	for _, name := range []string{"google", "openai", "anthropic", "ollama"} {
+		p := ProviderByName(name)
+		if p.Name() != name {
+			t.Errorf("ProviderByName(%q).Name() = %q, want %q", name, p.Name(), name)
+		}
+	}
+}
+
+func TestProviderByNameFallsBackToDefault(t *testing.T) {
+	for _, name := range []string{"", "bogus", "GPT4"} {
+		p := ProviderByName(name)
+		if p.Name() != Providers[0].Name() {
+			t.Errorf("ProviderByName(%q) = %q, want default provider %q", name, p.Name(), Providers[0].Name())
+		}
+	}
+}
+
+func TestOllamaProviderRequiresNoAPIKey(t *testing.T) {
+	p := ProviderByName("ollama")
+	if p.RequiresAPIKey() {
+		t.Error("ollamaProvider.RequiresAPIKey() = true, want false")
+	}
+	if envVars := p.EnvVars("unused"); len(envVars) != 0 {
+		t.Errorf("ollamaProvider.EnvVars() = %v, want empty", envVars)
+	}
+	payload := p.InitPayload("unused", "http://localhost:11434", "llama3.1")
+	if payload["apiKey"] != "" {
+		t.Errorf("ollamaProvider.InitPayload()[apiKey] = %q, want empty", payload["apiKey"])
+	}
+}
+
+func TestKeyedProvidersRequireAPIKey(t *testing.T) {
+	for _, name := range []string{"google", "openai", "anthropic"} {
+		p := ProviderByName(name)
+		if !p.RequiresAPIKey() {
+			t.Errorf("%s.RequiresAPIKey() = false, want true", name)
+		}
+		if envVars := p.EnvVars("sk-test"); len(envVars) == 0 {
+			t.Errorf("%s.EnvVars() returned no entries for a non-empty key", name)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+// ToolCallRequest is sent by the agent as MsgToolCallRequest and blocks
+// until the TUI replies with a matching ToolCallDecision.
+type ToolCallRequest struct {
+	ID       string                 `json:"id"`
+	ToolName string                 `json:"toolName"`
+	Args     map[string]interface{} `json:"args"`
+}
+
+// ToolCallDecision is the TUI's reply to a ToolCallRequest.
+type ToolCallDecision struct {
+	ID       string `json:"id"`
+	Decision string `json:"decision"`
+}
+
+// Decision values accepted by the agent for a tool_call_decision message.
+const (
+	DecisionApprove                = "approve"
+	DecisionDeny                   = "deny"
+	DecisionApproveAlwaysForTool   = "approve_always_for_tool"
+	DecisionApproveAlwaysInSession = "approve_always_in_session"
+)
+
+// alwaysGatedTools can never be auto-approved, even if the user asked to
+// always trust them - they can mutate the filesystem or run arbitrary
+// commands, so every call must be confirmed.
+var alwaysGatedTools = map[string]bool{
+	"run_command": true,
+	"write_file":  true,
+	"edit_file":   true,
+}
+
+func isAlwaysGated(toolName string) bool {
+	return alwaysGatedTools[toolName]
+}
+
+// readOnlyTools never mutate the filesystem or run commands, so it's
+// safe to default-approve them without a prior interactive session -
+// e.g. in headless/CI runs where no config.json exists yet.
+var readOnlyTools = map[string]bool{
+	"read_file":    true,
+	"list_files":   true,
+	"search_files": true,
+	"web_search":   true,
+	"url_extract":  true,
+}
+
+func isReadOnlyTool(toolName string) bool {
+	return readOnlyTools[toolName]
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestIsAlwaysGated(t *testing.T) {
+	tests := []struct {
+		tool string
+		want bool
+	}{
+		{"run_command", true},
+		{"write_file", true},
+		{"edit_file", true},
+		{"read_file", false},
+		{"list_files", false},
+		{"unknown_tool", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAlwaysGated(tt.tool); got != tt.want {
+			t.Errorf("isAlwaysGated(%q) = %v, want %v", tt.tool, got, tt.want)
+		}
+	}
+}
+
+func TestIsReadOnlyTool(t *testing.T) {
+	tests := []struct {
+		tool string
+		want bool
+	}{
+		{"read_file", true},
+		{"list_files", true},
+		{"search_files", true},
+		{"web_search", true},
+		{"url_extract", true},
+		{"run_command", false},
+		{"write_file", false},
+		{"edit_file", false},
+		{"unknown_tool", false},
+	}
+
+	for _, tt := range tests {
+		if got := isReadOnlyTool(tt.tool); got != tt.want {
+			t.Errorf("isReadOnlyTool(%q) = %v, want %v", tt.tool, got, tt.want)
+		}
+	}
+}
+
+func TestAlwaysGatedAndReadOnlyAreDisjoint(t *testing.T) {
+	for tool := range alwaysGatedTools {
+		if readOnlyTools[tool] {
+			t.Errorf("%q is marked both always-gated and read-only", tool)
+		}
+	}
+}